@@ -0,0 +1,183 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"genqlient-poc/internal/apq"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/gin-gonic/gin"
+)
+
+// persistedQueryStore backs the Automatic Persisted Queries (APQ) flow for
+// the generic /api/graphql endpoint. It defaults to an in-process LRU but
+// can be swapped (e.g. for a Redis-backed apq.PersistedQueryStore) before
+// the server starts.
+var persistedQueryStore apq.PersistedQueryStore = apq.NewLRUStore(1000)
+
+const persistedQueryNotFoundCode = "PersistedQueryNotFound"
+
+// graphqlExtensions mirrors the subset of the GraphQL-over-HTTP
+// "extensions" object that APQ cares about.
+type graphqlExtensions struct {
+	PersistedQuery *persistedQueryExtension `json:"persistedQuery,omitempty"`
+}
+
+type persistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// graphqlRequest is the standard GraphQL-over-HTTP request body, used by
+// both the POST and GET (query-string) forms of /api/graphql.
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    graphqlExtensions      `json:"extensions"`
+}
+
+// handleGraphQL proxies an arbitrary GraphQL query or mutation through the
+// shared graphql.Client, so callers don't need a generated wrapper for
+// every operation. It also implements the server side of Automatic
+// Persisted Queries (APQ): a request may omit `query` and send only the
+// query's sha256 hash, in which case the stored query text is replayed.
+func handleGraphQL(c *gin.Context) {
+	var req graphqlRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid GraphQL request body: " + err.Error(),
+		})
+		return
+	}
+
+	executeGraphQL(c, req)
+}
+
+// handleGraphQLGet supports GET /api/graphql?query=...&variables=...&extensions=...
+// for cacheable reads and for APQ hash-only lookups.
+func handleGraphQLGet(c *gin.Context) {
+	req := graphqlRequest{
+		Query:         c.Query("query"),
+		OperationName: c.Query("operationName"),
+	}
+
+	if raw := c.Query("variables"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid variables: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	if raw := c.Query("extensions"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &req.Extensions); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid extensions: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	executeGraphQL(c, req)
+}
+
+func executeGraphQL(c *gin.Context, req graphqlRequest) {
+	pq := req.Extensions.PersistedQuery
+
+	switch {
+	case req.Query == "" && pq != nil:
+		// Hash-only request: look up the previously stored query.
+		query, ok := persistedQueryStore.Get(pq.Sha256Hash)
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{
+				"errors": []gin.H{{
+					"message": "PersistedQueryNotFound",
+					"extensions": gin.H{
+						"code": persistedQueryNotFoundCode,
+					},
+				}},
+			})
+			return
+		}
+		req.Query = query
+
+	case req.Query != "" && pq != nil:
+		// Full query plus a hash: verify and store for future hash-only calls.
+		if sha256Hex(req.Query) != pq.Sha256Hash {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "provided sha256Hash does not match query",
+			})
+			return
+		}
+		persistedQueryStore.Put(pq.Sha256Hash, req.Query)
+
+	case req.Query == "":
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "query is required",
+		})
+		return
+	}
+
+	gqlReq := &graphql.Request{
+		Query:     req.Query,
+		Variables: req.Variables,
+		OpName:    req.OperationName,
+	}
+	var resp graphql.Response
+
+	if err := client.MakeRequest(c.Request.Context(), gqlReq, &resp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "GraphQL request failed: " + err.Error(),
+		})
+		return
+	}
+
+	if len(resp.Errors) == 0 && isMutation(req.Query) {
+		// The generic proxy can mutate anything the schema exposes, so
+		// unlike the REST handlers (which invalidate the one tag their
+		// own mutation affects) conservatively drop every REST-cacheable
+		// tag rather than risk serving a stale read after it.
+		ctx := c.Request.Context()
+		_ = responseCache.InvalidateTag(ctx, "users")
+		_ = responseCache.InvalidateTag(ctx, "posts")
+	}
+
+	body := gin.H{"data": resp.Data}
+	if len(resp.Errors) > 0 {
+		body["errors"] = resp.Errors
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// isMutation reports whether query is a GraphQL mutation operation, per
+// the GraphQL-over-HTTP convention of the operation type keyword leading
+// the document (optionally preceded by whitespace or "#" comment lines).
+func isMutation(query string) bool {
+	return strings.HasPrefix(strings.ToLower(leadingOperationKeyword(query)), "mutation")
+}
+
+// leadingOperationKeyword returns query with any whitespace and leading
+// "#" comment lines stripped, so the operation type keyword sits at the
+// very front of the result.
+func leadingOperationKeyword(query string) string {
+	lines := strings.Split(query, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+	}
+	return ""
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
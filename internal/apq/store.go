@@ -0,0 +1,85 @@
+// Package apq implements Automatic Persisted Queries (APQ) storage: mapping
+// a query's sha256 hash to its full text so clients can send the hash alone
+// on subsequent requests.
+package apq
+
+import (
+	"container/list"
+	"sync"
+)
+
+// PersistedQueryStore resolves a persisted query hash to its stored query
+// text. Implementations must be safe for concurrent use. The in-process
+// LRUStore below is the default; callers that need shared state across
+// instances (Redis, a file-backed store, ...) can supply their own.
+type PersistedQueryStore interface {
+	// Get returns the query text for hash and true if it is known.
+	Get(hash string) (query string, ok bool)
+	// Put records query under hash, evicting older entries if the store
+	// is capacity-bounded.
+	Put(hash, query string)
+}
+
+// LRUStore is an in-process, fixed-capacity PersistedQueryStore. It is the
+// default store used when no PersistedQueryStore is injected.
+type LRUStore struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type entry struct {
+	hash  string
+	query string
+}
+
+// NewLRUStore creates an LRUStore holding at most capacity entries. A
+// non-positive capacity defaults to 1000.
+func NewLRUStore(capacity int) *LRUStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &LRUStore{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements PersistedQueryStore.
+func (s *LRUStore) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[hash]
+	if !ok {
+		return "", false
+	}
+	s.ll.MoveToFront(el)
+	return el.Value.(*entry).query, true
+}
+
+// Put implements PersistedQueryStore.
+func (s *LRUStore) Put(hash, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[hash]; ok {
+		el.Value.(*entry).query = query
+		s.ll.MoveToFront(el)
+		return
+	}
+
+	el := s.ll.PushFront(&entry{hash: hash, query: query})
+	s.items[hash] = el
+
+	for s.ll.Len() > s.capacity {
+		oldest := s.ll.Back()
+		if oldest == nil {
+			break
+		}
+		s.ll.Remove(oldest)
+		delete(s.items, oldest.Value.(*entry).hash)
+	}
+}
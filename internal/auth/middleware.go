@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize rejects requests that don't carry an authenticated session and,
+// for those that do, attaches the resolved user ID to the gin.Context under
+// ContextUserIDKey so downstream handlers don't need to touch the session
+// directly.
+func Authorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := sessions.Default(c)
+
+		userID, ok := session.Get(sessionUserIDKey).(string)
+		if !ok || userID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "authentication required",
+			})
+			return
+		}
+
+		c.Set(ContextUserIDKey, userID)
+		c.Next()
+	}
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"genqlient-poc/generated"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// Handler implements the OIDC/OAuth login flow and provisions a
+// generated.User on first login.
+type Handler struct {
+	OAuth       *oauth2.Config
+	Client      graphql.Client
+	UserInfoURL string
+}
+
+// NewHandler builds a Handler for the given OAuth config. userInfoURL is
+// the provider's userinfo endpoint, used to resolve the logged-in email.
+func NewHandler(oauthConfig *oauth2.Config, client graphql.Client, userInfoURL string) *Handler {
+	return &Handler{OAuth: oauthConfig, Client: client, UserInfoURL: userInfoURL}
+}
+
+// Login redirects to the provider's consent screen, stashing a CSRF state
+// value in the session to be checked in Callback.
+func (h *Handler) Login(c *gin.Context) {
+	state, err := randomState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to start login: " + err.Error(),
+		})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set(sessionStateKey, state)
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to persist session: " + err.Error(),
+		})
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, h.OAuth.AuthCodeURL(state))
+}
+
+// Callback exchanges the authorization code for a token, resolves the
+// user's email, auto-provisions a generated.User on first login, and
+// stores the resulting user ID in the session.
+func (h *Handler) Callback(c *gin.Context) {
+	session := sessions.Default(c)
+
+	state, _ := session.Get(sessionStateKey).(string)
+	if state == "" || c.Query("state") != state {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid OAuth state"})
+		return
+	}
+	session.Delete(sessionStateKey)
+
+	ctx := c.Request.Context()
+
+	token, err := h.OAuth.Exchange(ctx, c.Query("code"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "failed to exchange code: " + err.Error(),
+		})
+		return
+	}
+
+	email, err := h.fetchEmail(ctx, token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "failed to resolve user email: " + err.Error(),
+		})
+		return
+	}
+
+	userID, err := h.resolveOrProvisionUser(ctx, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to provision user: " + err.Error(),
+		})
+		return
+	}
+
+	session.Set(sessionEmailKey, email)
+	session.Set(sessionUserIDKey, userID)
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to persist session: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "login successful",
+		"user_id": userID,
+		"email":   email,
+	})
+}
+
+// resolveOrProvisionUser looks up email via generated.GetUserByEmail; if no
+// user exists yet, it issues generated.CreateUser to provision one, mirroring
+// the auto-create-on-first-login pattern.
+func (h *Handler) resolveOrProvisionUser(ctx context.Context, email string) (string, error) {
+	existing, err := generated.GetUserByEmail(ctx, h.Client, email)
+	if err != nil {
+		return "", fmt.Errorf("looking up user by email: %w", err)
+	}
+	if existing != nil && existing.GetUserByEmail() != nil {
+		return existing.GetUserByEmail().Id, nil
+	}
+
+	created, err := generated.CreateUser(ctx, h.Client, emailLocalPart(email), email, 0)
+	if err != nil {
+		return "", fmt.Errorf("provisioning user: %w", err)
+	}
+	return created.GetCreateUser().Id, nil
+}
+
+// fetchEmail calls the provider's userinfo endpoint with the access token
+// and extracts the email claim.
+func (h *Handler) fetchEmail(ctx context.Context, token *oauth2.Token) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.UserInfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	token.SetAuthHeader(req)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("userinfo endpoint returned %s", resp.Status)
+	}
+
+	var info struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return "", err
+	}
+	if info.Email == "" {
+		return "", fmt.Errorf("userinfo response had no email claim")
+	}
+	return info.Email, nil
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// emailLocalPart derives a display name from an email address for
+// first-login provisioning, e.g. "jane.doe@example.com" -> "jane.doe".
+func emailLocalPart(email string) string {
+	for i, r := range email {
+		if r == '@' {
+			return email[:i]
+		}
+	}
+	return email
+}
@@ -0,0 +1,18 @@
+// Package auth provides session-backed OIDC login with first-login user
+// auto-provisioning, and the Authorize() middleware that protects routes
+// behind an established session.
+package auth
+
+const (
+	// sessionEmailKey stores the authenticated user's email in the session.
+	sessionEmailKey = "user_email"
+	// sessionUserIDKey stores the resolved generated.User ID in the session.
+	sessionUserIDKey = "user_id"
+	// sessionStateKey stores the OAuth CSRF state between /auth/login and
+	// /auth/callback.
+	sessionStateKey = "oauth_state"
+)
+
+// ContextUserIDKey is the gin.Context key Authorize() sets once a request
+// has been authenticated, for downstream handlers to read.
+const ContextUserIDKey = "userID"
@@ -0,0 +1,52 @@
+// Package bus implements the generic request-routing machinery shared by
+// the read (query) and write (command) sides of the CQRS split: typed
+// handlers registered on a Bus by request type and dispatched by exact
+// type match. The command and query packages each wrap this with their
+// own names so callers never import internal/bus directly.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Handler answers a single request type Req with a result Res.
+type Handler[Req any, Res any] interface {
+	Handle(ctx context.Context, req Req) (Res, error)
+}
+
+// Bus routes a request to the handler registered for its concrete type.
+type Bus struct {
+	handlers map[reflect.Type]any
+}
+
+// New creates an empty Bus.
+func New() *Bus {
+	return &Bus{handlers: make(map[reflect.Type]any)}
+}
+
+// Register associates Req's type with h. Registering the same Req type
+// twice replaces the previous handler.
+func Register[Req any, Res any](b *Bus, h Handler[Req, Res]) {
+	var zero Req
+	b.handlers[reflect.TypeOf(zero)] = h
+}
+
+// Dispatch finds the handler registered for req's type and invokes it.
+// kind names the caller ("query" or "command") for error messages.
+func Dispatch[Req any, Res any](ctx context.Context, b *Bus, kind string, req Req) (Res, error) {
+	var zero Res
+
+	raw, ok := b.handlers[reflect.TypeOf(req)]
+	if !ok {
+		return zero, fmt.Errorf("%s: no handler registered for %T", kind, req)
+	}
+
+	handler, ok := raw.(Handler[Req, Res])
+	if !ok {
+		return zero, fmt.Errorf("%s: handler for %T has an unexpected result type", kind, req)
+	}
+
+	return handler.Handle(ctx, req)
+}
@@ -0,0 +1,21 @@
+// Package cache implements a pluggable response cache for GET endpoints,
+// with ETag / If-None-Match support and tag-based invalidation on writes.
+package cache
+
+import "context"
+
+// Entry is a cached HTTP response.
+type Entry struct {
+	Status      int
+	ContentType string
+	Body        []byte
+	ETag        string
+}
+
+// ResponseCache stores rendered GET responses keyed by route + query +
+// user, tagged so a write can invalidate every cached read it affects.
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+	Set(ctx context.Context, key string, entry *Entry, tags []string) error
+	InvalidateTag(ctx context.Context, tag string) error
+}
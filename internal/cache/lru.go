@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRUCache is the default, in-process ResponseCache.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	tagKeys  map[string]map[string]struct{}
+}
+
+type lruEntry struct {
+	key   string
+	entry *Entry
+	tags  []string
+}
+
+// NewLRUCache creates an LRUCache holding at most capacity entries. A
+// non-positive capacity defaults to 1000.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		tagKeys:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements ResponseCache.
+func (c *LRUCache) Get(_ context.Context, key string) (*Entry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).entry, true, nil
+}
+
+// Set implements ResponseCache.
+func (c *LRUCache) Set(_ context.Context, key string, entry *Entry, tags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.untagLocked(el.Value.(*lruEntry))
+		el.Value = &lruEntry{key: key, entry: entry, tags: tags}
+		c.ll.MoveToFront(el)
+	} else {
+		el := c.ll.PushFront(&lruEntry{key: key, entry: entry, tags: tags})
+		c.items[key] = el
+	}
+	c.tagLocked(key, tags)
+
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.untagLocked(oldest.Value.(*lruEntry))
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// InvalidateTag implements ResponseCache.
+func (c *LRUCache) InvalidateTag(_ context.Context, tag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tagKeys[tag] {
+		el, ok := c.items[key]
+		if !ok {
+			continue
+		}
+		c.untagLocked(el.Value.(*lruEntry))
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+	delete(c.tagKeys, tag)
+	return nil
+}
+
+func (c *LRUCache) tagLocked(key string, tags []string) {
+	for _, tag := range tags {
+		set, ok := c.tagKeys[tag]
+		if !ok {
+			set = make(map[string]struct{})
+			c.tagKeys[tag] = set
+		}
+		set[key] = struct{}{}
+	}
+}
+
+func (c *LRUCache) untagLocked(e *lruEntry) {
+	for _, tag := range e.tags {
+		set, ok := c.tagKeys[tag]
+		if !ok {
+			continue
+		}
+		delete(set, e.key)
+		if len(set) == 0 {
+			delete(c.tagKeys, tag)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"genqlient-poc/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagsFunc derives the cache tags a route's response should be filed
+// under, so a later InvalidateTag call can evict every cached response it
+// affects.
+type TagsFunc func(c *gin.Context) []string
+
+// Middleware caches GET responses in rc, keyed by route, query params, and
+// the authenticated user, and serves conditional 304s via ETag /
+// If-None-Match. Non-GET requests pass through untouched.
+func Middleware(rc ResponseCache, tags TagsFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		// A response with no tags can never be invalidated by a later
+		// write, so don't cache it at all rather than risk serving it
+		// stale forever.
+		cacheTags := tags(c)
+		if len(cacheTags) == 0 {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := cacheKey(c)
+
+		if entry, ok, err := rc.Get(ctx, key); err == nil && ok {
+			if inm := c.GetHeader("If-None-Match"); inm != "" && inm == entry.ETag {
+				c.Status(http.StatusNotModified)
+				c.Abort()
+				return
+			}
+			c.Header("ETag", entry.ETag)
+			c.Data(entry.Status, entry.ContentType, entry.Body)
+			c.Abort()
+			return
+		}
+
+		writer := &bufferingWriter{ResponseWriter: c.Writer, buf: &bytes.Buffer{}, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+
+		if c.IsAborted() || writer.status >= http.StatusBadRequest {
+			return
+		}
+
+		etag := computeETag(writer.buf.Bytes())
+		entry := &Entry{
+			Status:      writer.status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.buf.Bytes(),
+			ETag:        etag,
+		}
+		c.Header("ETag", etag)
+		_ = rc.Set(ctx, key, entry, cacheTags)
+	}
+}
+
+// cacheKey identifies a cached response by route, query string, and
+// authenticated user, so one user's cached response is never served to
+// another.
+func cacheKey(c *gin.Context) string {
+	userID, _ := c.Get(auth.ContextUserIDKey)
+	return fmt.Sprintf("%v|%s|%s", userID, c.Request.URL.Path, c.Request.URL.RawQuery)
+}
+
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// bufferingWriter captures the response body as it's written so it can be
+// stored in the cache alongside forwarding it to the real client.
+type bufferingWriter struct {
+	gin.ResponseWriter
+	buf    *bytes.Buffer
+	status int
+}
+
+func (w *bufferingWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	w.buf.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bufferingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a ResponseCache backed by Redis, for deployments that run
+// more than one instance of this service and need cached responses and
+// tag invalidation shared across them.
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache creates a RedisCache. prefix namespaces all keys this
+// cache writes, so it can share a Redis instance with other data.
+func NewRedisCache(client *redis.Client, prefix string) *RedisCache {
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+func (c *RedisCache) entryKey(key string) string  { return c.prefix + "entry:" + key }
+func (c *RedisCache) tagKey(tag string) string    { return c.prefix + "tag:" + tag }
+func (c *RedisCache) tagsOfKey(key string) string { return c.prefix + "tags:" + key }
+
+// Get implements ResponseCache.
+func (c *RedisCache) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	raw, err := c.client.Get(ctx, c.entryKey(key)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false, err
+	}
+	return &entry, true, nil
+}
+
+// Set implements ResponseCache. Like LRUCache.Set, it first drops key from
+// any tag sets it was previously (but is no longer) a member of, so a
+// later InvalidateTag on a stale tag can't still find and evict it.
+func (c *RedisCache) Set(ctx context.Context, key string, entry *Entry, tags []string) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := c.client.Set(ctx, c.entryKey(key), raw, 0).Err(); err != nil {
+		return err
+	}
+
+	staleTags, err := c.client.SMembers(ctx, c.tagsOfKey(key)).Result()
+	if err != nil {
+		return err
+	}
+	for _, tag := range staleTags {
+		if err := c.client.SRem(ctx, c.tagKey(tag), key).Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.client.Del(ctx, c.tagsOfKey(key)).Err(); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := c.client.SAdd(ctx, c.tagKey(tag), key).Err(); err != nil {
+			return err
+		}
+		if err := c.client.SAdd(ctx, c.tagsOfKey(key), tag).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InvalidateTag implements ResponseCache.
+func (c *RedisCache) InvalidateTag(ctx context.Context, tag string) error {
+	keys, err := c.client.SMembers(ctx, c.tagKey(tag)).Result()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	delKeys := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		// Like LRUCache.InvalidateTag, drop key from every other tag set it
+		// was filed under too, since its tag index is deleted below.
+		otherTags, err := c.client.SMembers(ctx, c.tagsOfKey(key)).Result()
+		if err != nil {
+			return err
+		}
+		for _, other := range otherTags {
+			if err := c.client.SRem(ctx, c.tagKey(other), key).Err(); err != nil {
+				return err
+			}
+		}
+		delKeys = append(delKeys, c.entryKey(key), c.tagsOfKey(key))
+	}
+	if err := c.client.Del(ctx, delKeys...).Err(); err != nil {
+		return err
+	}
+	return c.client.Del(ctx, c.tagKey(tag)).Err()
+}
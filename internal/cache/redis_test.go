@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisCache(t *testing.T) *RedisCache {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisCache(client, "test:")
+}
+
+func TestRedisCacheInvalidateTagEvictsTaggedEntries(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRedisCache(t)
+
+	_ = c.Set(ctx, "user:1", &Entry{Body: []byte("a")}, []string{"users"})
+	_ = c.Set(ctx, "post:1", &Entry{Body: []byte("b")}, []string{"posts"})
+
+	if err := c.InvalidateTag(ctx, "users"); err != nil {
+		t.Fatalf("InvalidateTag returned error: %v", err)
+	}
+
+	if _, ok, _ := c.Get(ctx, "user:1"); ok {
+		t.Error("user:1 still cached after invalidating its tag")
+	}
+	if _, ok, _ := c.Get(ctx, "post:1"); !ok {
+		t.Error("post:1 evicted by an unrelated tag invalidation")
+	}
+}
+
+func TestRedisCacheSetIsSymmetricOnRetag(t *testing.T) {
+	ctx := context.Background()
+	c := newTestRedisCache(t)
+
+	_ = c.Set(ctx, "user:1", &Entry{Body: []byte("a")}, []string{"users"})
+	// Re-cache the same key under a different tag set.
+	_ = c.Set(ctx, "user:1", &Entry{Body: []byte("a2")}, []string{"admins"})
+
+	if err := c.InvalidateTag(ctx, "users"); err != nil {
+		t.Fatalf("InvalidateTag returned error: %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "user:1"); !ok {
+		t.Fatal("user:1 was evicted by invalidating a tag it's no longer a member of")
+	}
+
+	if err := c.InvalidateTag(ctx, "admins"); err != nil {
+		t.Fatalf("InvalidateTag returned error: %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "user:1"); ok {
+		t.Error("user:1 still cached after invalidating its current tag")
+	}
+}
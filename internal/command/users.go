@@ -0,0 +1,59 @@
+package command
+
+import (
+	"context"
+
+	"genqlient-poc/generated"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// CreateUserCommand creates a new user.
+type CreateUserCommand struct {
+	Name  string
+	Email string
+	Age   int
+}
+
+// CreateUserHandler handles CreateUserCommand via generated.CreateUser.
+type CreateUserHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[CreateUserCommand, *generated.CreateUserResponse].
+func (h CreateUserHandler) Handle(ctx context.Context, cmd CreateUserCommand) (*generated.CreateUserResponse, error) {
+	return generated.CreateUser(ctx, h.Client, cmd.Name, cmd.Email, cmd.Age)
+}
+
+// UpdateUserCommand updates an existing user.
+type UpdateUserCommand struct {
+	ID    string
+	Name  string
+	Email string
+	Age   int
+}
+
+// UpdateUserHandler handles UpdateUserCommand via generated.UpdateUser.
+type UpdateUserHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[UpdateUserCommand, *generated.UpdateUserResponse].
+func (h UpdateUserHandler) Handle(ctx context.Context, cmd UpdateUserCommand) (*generated.UpdateUserResponse, error) {
+	return generated.UpdateUser(ctx, h.Client, cmd.ID, cmd.Name, cmd.Email, cmd.Age)
+}
+
+// DeleteUserCommand deletes a user by ID.
+type DeleteUserCommand struct {
+	ID string
+}
+
+// DeleteUserHandler handles DeleteUserCommand via generated.DeleteUser.
+type DeleteUserHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[DeleteUserCommand, *generated.DeleteUserResponse].
+func (h DeleteUserHandler) Handle(ctx context.Context, cmd DeleteUserCommand) (*generated.DeleteUserResponse, error) {
+	return generated.DeleteUser(ctx, h.Client, cmd.ID)
+}
@@ -0,0 +1,66 @@
+// Package gqlerror maps the GraphQL errors genqlient hands back (a wrapped
+// vektah/gqlparser gqlerror.List) onto HTTP statuses and a structured
+// response body, so handlers stop guessing intent from nil checks.
+package gqlerror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Response is the structured body returned to API clients for a failed
+// GraphQL operation.
+type Response struct {
+	Errors []ErrorDetail `json:"errors"`
+}
+
+// ErrorDetail mirrors a single entry of a GraphQL error list.
+type ErrorDetail struct {
+	Message    string                 `json:"message"`
+	Path       interface{}            `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// codeStatus maps a well-known `extensions.code` value to the HTTP status
+// it should surface as. Codes with no entry here fall back to 500.
+var codeStatus = map[string]int{
+	"NOT_FOUND":                 http.StatusNotFound,
+	"UNAUTHENTICATED":           http.StatusUnauthorized,
+	"FORBIDDEN":                 http.StatusForbidden,
+	"BAD_USER_INPUT":            http.StatusBadRequest,
+	"PERSISTED_QUERY_NOT_FOUND": http.StatusOK,
+}
+
+// StatusAndBody inspects err for a wrapped gqlerror.List, as returned by
+// genqlient when the server responds with GraphQL errors, and maps the
+// first error's extensions.code to an HTTP status plus a structured body
+// carrying every error's path and extensions for client debuggability.
+// Errors that aren't a gqlerror.List fall back to 500 with a generic body.
+func StatusAndBody(err error) (int, Response) {
+	var list gqlerror.List
+	if !errors.As(err, &list) || len(list) == 0 {
+		return http.StatusInternalServerError, Response{
+			Errors: []ErrorDetail{{Message: err.Error()}},
+		}
+	}
+
+	status := http.StatusInternalServerError
+	if code, _ := list[0].Extensions["code"].(string); code != "" {
+		if s, ok := codeStatus[code]; ok {
+			status = s
+		}
+	}
+
+	details := make([]ErrorDetail, 0, len(list))
+	for _, e := range list {
+		details = append(details, ErrorDetail{
+			Message:    e.Message,
+			Path:       e.Path,
+			Extensions: e.Extensions,
+		})
+	}
+
+	return status, Response{Errors: details}
+}
@@ -0,0 +1,129 @@
+// Package loader implements a request-scoped, dataloader-style batcher:
+// concurrent Load calls for different keys made within a short window are
+// coalesced into a single batch function call and the results scattered
+// back to each caller, addressing the N+1 pattern that appears as soon as
+// a handler needs several related lookups per request.
+package loader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BatchFunc resolves a deduplicated set of keys in one call.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Options configures a BatchLoader's batching window.
+type Options struct {
+	// Wait is how long to accumulate keys before firing the batch.
+	// Defaults to 10ms.
+	Wait time.Duration
+	// MaxBatch caps the number of keys per batch; 0 means no cap.
+	MaxBatch int
+}
+
+// BatchLoader coalesces Load calls for the same loader instance that occur
+// within Options.Wait of each other into a single BatchFunc call. It is not
+// safe to share across unrelated requests; create one per request scope.
+type BatchLoader[K comparable, V any] struct {
+	batchFn  BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu    sync.Mutex
+	batch *pendingBatch[K, V]
+}
+
+type pendingBatch[K comparable, V any] struct {
+	keys    []K
+	waiters map[K][]chan batchResult[V]
+	timer   *time.Timer
+}
+
+type batchResult[V any] struct {
+	value V
+	err   error
+}
+
+// NewBatchLoader creates a BatchLoader that resolves keys via fn.
+func NewBatchLoader[K comparable, V any](fn BatchFunc[K, V], opts Options) *BatchLoader[K, V] {
+	wait := opts.Wait
+	if wait <= 0 {
+		wait = 10 * time.Millisecond
+	}
+	return &BatchLoader[K, V]{batchFn: fn, wait: wait, maxBatch: opts.MaxBatch}
+}
+
+// Load enqueues key into the current batch (starting one if needed) and
+// blocks until that batch has been resolved.
+func (l *BatchLoader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	ch := make(chan batchResult[V], 1)
+
+	l.mu.Lock()
+	b := l.batch
+	if b == nil {
+		b = &pendingBatch[K, V]{waiters: make(map[K][]chan batchResult[V])}
+		l.batch = b
+		b.timer = time.AfterFunc(l.wait, func() { l.fire(ctx, b) })
+	}
+	b.keys = append(b.keys, key)
+	b.waiters[key] = append(b.waiters[key], ch)
+	fireNow := l.maxBatch > 0 && len(b.keys) >= l.maxBatch
+	l.mu.Unlock()
+
+	if fireNow {
+		b.timer.Stop()
+		l.fire(ctx, b)
+	}
+
+	res := <-ch
+	return res.value, res.err
+}
+
+// fire resolves a pending batch, if it hasn't already been resolved by a
+// concurrent call, and delivers a result to every waiter.
+func (l *BatchLoader[K, V]) fire(ctx context.Context, b *pendingBatch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	values, err := l.batchFn(ctx, dedupeKeys(b.keys))
+
+	for key, waiters := range b.waiters {
+		res := resultFor(key, values, err)
+		for _, ch := range waiters {
+			ch <- res
+			close(ch)
+		}
+	}
+}
+
+func resultFor[K comparable, V any](key K, values map[K]V, batchErr error) batchResult[V] {
+	if batchErr != nil {
+		return batchResult[V]{err: batchErr}
+	}
+	v, ok := values[key]
+	if !ok {
+		return batchResult[V]{err: fmt.Errorf("loader: no result for key %v", key)}
+	}
+	return batchResult[V]{value: v}
+}
+
+func dedupeKeys[K comparable](keys []K) []K {
+	seen := make(map[K]struct{}, len(keys))
+	out := make([]K, 0, len(keys))
+	for _, k := range keys {
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchLoaderCoalescesConcurrentLoads(t *testing.T) {
+	var calls int32
+	var gotKeys [][]int
+
+	var mu sync.Mutex
+	loader := NewBatchLoader(func(_ context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+
+		mu.Lock()
+		gotKeys = append(gotKeys, append([]int(nil), keys...))
+		mu.Unlock()
+
+		out := make(map[int]string, len(keys))
+		for _, k := range keys {
+			out[k] = fmt.Sprintf("value-%d", k)
+		}
+		return out, nil
+	}, Options{Wait: 20 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	keys := []int{1, 2, 1, 3}
+	for i, key := range keys {
+		i, key := i, key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := loader.Load(context.Background(), key)
+			if err != nil {
+				t.Errorf("Load(%d) returned error: %v", key, err)
+				return
+			}
+			results[i] = v
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("batch func called %d times, want 1", got)
+	}
+	if len(gotKeys) != 1 || len(gotKeys[0]) != 3 {
+		t.Fatalf("batch func saw keys %v, want 3 deduped keys", gotKeys)
+	}
+
+	want := []string{"value-1", "value-2", "value-1", "value-3"}
+	for i, w := range want {
+		if results[i] != w {
+			t.Errorf("results[%d] = %q, want %q", i, results[i], w)
+		}
+	}
+}
+
+func TestBatchLoaderMaxBatchFiresEarly(t *testing.T) {
+	var calls int32
+
+	loader := NewBatchLoader(func(_ context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+		out := make(map[int]string, len(keys))
+		for _, k := range keys {
+			out[k] = fmt.Sprintf("value-%d", k)
+		}
+		return out, nil
+	}, Options{Wait: time.Hour, MaxBatch: 2})
+
+	var wg sync.WaitGroup
+	for _, key := range []int{1, 2} {
+		key := key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := loader.Load(context.Background(), key); err != nil {
+				t.Errorf("Load(%d) returned error: %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("batch func called %d times, want 1 (MaxBatch should have fired the batch without waiting)", got)
+	}
+}
+
+func TestBatchLoaderScattersErrorToEveryWaiter(t *testing.T) {
+	wantErr := fmt.Errorf("boom")
+	loader := NewBatchLoader(func(_ context.Context, _ []int) (map[int]string, error) {
+		return nil, wantErr
+	}, Options{Wait: 5 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i, key := range []int{1, 2, 3} {
+		i, key := i, key
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := loader.Load(context.Background(), key)
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != wantErr {
+			t.Errorf("errs[%d] = %v, want %v", i, err, wantErr)
+		}
+	}
+}
+
+func TestBatchLoaderMissingKeyReturnsError(t *testing.T) {
+	loader := NewBatchLoader(func(_ context.Context, _ []int) (map[int]string, error) {
+		return map[int]string{}, nil
+	}, Options{Wait: 5 * time.Millisecond})
+
+	if _, err := loader.Load(context.Background(), 1); err == nil {
+		t.Fatal("Load with no result for key: got nil error, want an error")
+	}
+}
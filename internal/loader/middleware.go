@@ -0,0 +1,28 @@
+package loader
+
+import (
+	"context"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey struct{}
+
+// Middleware attaches a fresh, request-scoped UserLoader to every request,
+// so handlers downstream in the same request can coalesce lookups via
+// From(ctx).
+func Middleware(client graphql.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), contextKey{}, newUserLoader(client))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// From returns the UserLoader attached to ctx by Middleware, or nil if none
+// is present.
+func From(ctx context.Context) *UserLoader {
+	loader, _ := ctx.Value(contextKey{}).(*UserLoader)
+	return loader
+}
@@ -0,0 +1,41 @@
+package loader
+
+import (
+	"context"
+	"time"
+
+	"genqlient-poc/generated"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// UserLoader batches and deduplicates single-user lookups (the
+// generated.GetUser(ctx, client, id) call pattern) within one request into
+// a single `users(ids: […])` GraphQL call.
+type UserLoader struct {
+	batch *BatchLoader[string, *generated.User]
+}
+
+func newUserLoader(client graphql.Client) *UserLoader {
+	return &UserLoader{
+		batch: NewBatchLoader(func(ctx context.Context, ids []string) (map[string]*generated.User, error) {
+			resp, err := generated.GetUsersByIDs(ctx, client, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			users := make(map[string]*generated.User, len(resp.GetUsers()))
+			for _, u := range resp.GetUsers() {
+				u := u
+				users[u.Id] = &u
+			}
+			return users, nil
+		}, Options{Wait: 10 * time.Millisecond}),
+	}
+}
+
+// Load resolves a single user by ID, coalescing with any other Load calls
+// made on this loader within the batching window.
+func (l *UserLoader) Load(ctx context.Context, id string) (*generated.User, error) {
+	return l.batch.Load(ctx, id)
+}
@@ -0,0 +1,32 @@
+// Package query implements the read side of the CQRS split: typed query
+// handlers registered on a Bus and dispatched by request type, so the Gin
+// layer never calls genqlient-generated functions directly.
+package query
+
+import (
+	"context"
+
+	"genqlient-poc/internal/bus"
+)
+
+// Handler answers a single query type Req with a result Res.
+type Handler[Req any, Res any] = bus.Handler[Req, Res]
+
+// Bus routes a query to the handler registered for its concrete type.
+type Bus = bus.Bus
+
+// NewBus creates an empty Bus.
+func NewBus() *Bus {
+	return bus.New()
+}
+
+// Register associates Req's type with h. Registering the same Req type
+// twice replaces the previous handler.
+func Register[Req any, Res any](b *Bus, h Handler[Req, Res]) {
+	bus.Register[Req, Res](b, h)
+}
+
+// Dispatch finds the handler registered for req's type and invokes it.
+func Dispatch[Req any, Res any](ctx context.Context, b *Bus, req Req) (Res, error) {
+	return bus.Dispatch[Req, Res](ctx, b, "query", req)
+}
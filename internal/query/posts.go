@@ -0,0 +1,37 @@
+package query
+
+import (
+	"context"
+
+	"genqlient-poc/generated"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// ListPostsQuery fetches every post.
+type ListPostsQuery struct{}
+
+// ListPostsHandler handles ListPostsQuery via generated.GetPosts.
+type ListPostsHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[ListPostsQuery, *generated.GetPostsResponse].
+func (h ListPostsHandler) Handle(ctx context.Context, _ ListPostsQuery) (*generated.GetPostsResponse, error) {
+	return generated.GetPosts(ctx, h.Client)
+}
+
+// FindPostByIDQuery fetches a single post by ID.
+type FindPostByIDQuery struct {
+	ID string
+}
+
+// FindPostByIDHandler handles FindPostByIDQuery via generated.GetPost.
+type FindPostByIDHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[FindPostByIDQuery, *generated.GetPostResponse].
+func (h FindPostByIDHandler) Handle(ctx context.Context, q FindPostByIDQuery) (*generated.GetPostResponse, error) {
+	return generated.GetPost(ctx, h.Client, q.ID)
+}
@@ -0,0 +1,52 @@
+package query
+
+import (
+	"context"
+
+	"genqlient-poc/generated"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+// ListUsersQuery fetches every user.
+type ListUsersQuery struct{}
+
+// ListUsersHandler handles ListUsersQuery via generated.GetUsers.
+type ListUsersHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[ListUsersQuery, *generated.GetUsersResponse].
+func (h ListUsersHandler) Handle(ctx context.Context, _ ListUsersQuery) (*generated.GetUsersResponse, error) {
+	return generated.GetUsers(ctx, h.Client)
+}
+
+// FindUserByIDQuery fetches a single user by ID.
+type FindUserByIDQuery struct {
+	ID string
+}
+
+// FindUserByIDHandler handles FindUserByIDQuery via generated.GetUser.
+type FindUserByIDHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[FindUserByIDQuery, *generated.GetUserResponse].
+func (h FindUserByIDHandler) Handle(ctx context.Context, q FindUserByIDQuery) (*generated.GetUserResponse, error) {
+	return generated.GetUser(ctx, h.Client, q.ID)
+}
+
+// SearchUsersByNameQuery fetches users whose name matches Name.
+type SearchUsersByNameQuery struct {
+	Name string
+}
+
+// SearchUsersByNameHandler handles SearchUsersByNameQuery via generated.SearchUsers.
+type SearchUsersByNameHandler struct {
+	Client graphql.Client
+}
+
+// Handle implements Handler[SearchUsersByNameQuery, *generated.SearchUsersResponse].
+func (h SearchUsersByNameHandler) Handle(ctx context.Context, q SearchUsersByNameQuery) (*generated.SearchUsersResponse, error) {
+	return generated.SearchUsers(ctx, h.Client, q.Name)
+}
@@ -1,25 +1,91 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 
 	"genqlient-poc/generated"
+	"genqlient-poc/internal/auth"
+	"genqlient-poc/internal/cache"
+	"genqlient-poc/internal/command"
+	"genqlient-poc/internal/gqlerror"
+	"genqlient-poc/internal/loader"
+	"genqlient-poc/internal/query"
 
 	"github.com/Khan/genqlient/graphql"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
 )
 
 // GraphQL client
 var client graphql.Client
 
+// CQRS buses. Gin handlers only bind JSON/path params and dispatch through
+// these; they never call generated.* directly.
+var (
+	queryBus   = query.NewBus()
+	commandBus = command.NewBus()
+)
+
+// responseCache backs the ETag-aware GET cache. It defaults to an
+// in-process LRU but can be swapped for cache.NewRedisCache before the
+// server starts.
+var responseCache cache.ResponseCache = cache.NewLRUCache(1000)
+
+// apiCacheTags tags cached GET responses by the /api resource they read,
+// so mutating that resource can invalidate every cached read of it.
+func apiCacheTags(c *gin.Context) []string {
+	switch {
+	case strings.HasPrefix(c.Request.URL.Path, "/api/users"):
+		return []string{"users"}
+	case strings.HasPrefix(c.Request.URL.Path, "/api/posts"):
+		return []string{"posts"}
+	default:
+		return nil
+	}
+}
+
+func registerHandlers(c graphql.Client) {
+	query.Register[query.ListUsersQuery, *generated.GetUsersResponse](queryBus, query.ListUsersHandler{Client: c})
+	query.Register[query.FindUserByIDQuery, *generated.GetUserResponse](queryBus, query.FindUserByIDHandler{Client: c})
+	query.Register[query.SearchUsersByNameQuery, *generated.SearchUsersResponse](queryBus, query.SearchUsersByNameHandler{Client: c})
+	query.Register[query.ListPostsQuery, *generated.GetPostsResponse](queryBus, query.ListPostsHandler{Client: c})
+	query.Register[query.FindPostByIDQuery, *generated.GetPostResponse](queryBus, query.FindPostByIDHandler{Client: c})
+
+	command.Register[command.CreateUserCommand, *generated.CreateUserResponse](commandBus, command.CreateUserHandler{Client: c})
+	command.Register[command.UpdateUserCommand, *generated.UpdateUserResponse](commandBus, command.UpdateUserHandler{Client: c})
+	command.Register[command.DeleteUserCommand, *generated.DeleteUserResponse](commandBus, command.DeleteUserHandler{Client: c})
+}
+
+// newAuthHandler builds the OIDC login handler from environment
+// configuration, mirroring the PORT env-var convention used elsewhere.
+func newAuthHandler(c graphql.Client) *auth.Handler {
+	oauthConfig := &oauth2.Config{
+		ClientID:     os.Getenv("OAUTH_CLIENT_ID"),
+		ClientSecret: os.Getenv("OAUTH_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OAUTH_REDIRECT_URL"),
+		Scopes:       []string{"openid", "email", "profile"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  os.Getenv("OAUTH_AUTH_URL"),
+			TokenURL: os.Getenv("OAUTH_TOKEN_URL"),
+		},
+	}
+
+	return auth.NewHandler(oauthConfig, c, os.Getenv("OAUTH_USERINFO_URL"))
+}
+
 func main() {
 	// Initialize GraphQL client
 	httpClient := &http.Client{}
 	client = graphql.NewClient("http://localhost:4000/graphql", httpClient)
+	registerHandlers(client)
+	authHandler := newAuthHandler(client)
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -38,6 +104,13 @@ func main() {
 		c.Next()
 	})
 
+	// Session store backing both the OAuth login flow and Authorize().
+	sessionSecret := os.Getenv("SESSION_SECRET")
+	if sessionSecret == "" {
+		sessionSecret = "dev-session-secret"
+	}
+	r.Use(sessions.Sessions("genqlient_session", cookie.NewStore([]byte(sessionSecret))))
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -46,8 +119,15 @@ func main() {
 		})
 	})
 
-	// GraphQL proxy endpoints
-	api := r.Group("/api")
+	// OIDC/OAuth login flow
+	authGroup := r.Group("/auth")
+	{
+		authGroup.GET("/login", authHandler.Login)
+		authGroup.GET("/callback", authHandler.Callback)
+	}
+
+	// GraphQL proxy endpoints, behind session auth
+	api := r.Group("/api", auth.Authorize(), loader.Middleware(client), cache.Middleware(responseCache, apiCacheTags))
 	{
 		// Get all users
 		api.GET("/users", getUsers)
@@ -64,6 +144,9 @@ func main() {
 		// Get post by ID
 		api.GET("/posts/:id", getPostByID)
 
+		// Post feed with each post's author embedded, via the DataLoader
+		api.GET("/posts/feed", getPostsFeed)
+
 		// Create user
 		api.POST("/users", createUser)
 
@@ -72,6 +155,10 @@ func main() {
 
 		// Delete user
 		api.DELETE("/users/:id", deleteUser)
+
+		// Generic GraphQL pass-through, with Automatic Persisted Queries (APQ)
+		api.POST("/graphql", handleGraphQL)
+		api.GET("/graphql", handleGraphQLGet)
 	}
 
 	// Start server
@@ -87,21 +174,29 @@ func main() {
 	log.Fatal(r.Run(":" + port))
 }
 
-// Handler functions using genqlient
+// respondGQLError maps a genqlient error onto the HTTP status its
+// extensions.code implies and writes the structured GraphQL error body, so a
+// server-signalled NOT_FOUND surfaces as 404. A response that carries no
+// error but a null (or false) result is still reported as 404 by the
+// handler's own existence check.
+func respondGQLError(c *gin.Context, err error) {
+	status, body := gqlerror.StatusAndBody(err)
+	c.JSON(status, body)
+}
+
+// Handler functions. Each one only binds JSON/path params and dispatches
+// onto the query/command buses; the genqlient calls live in
+// internal/query and internal/command.
 
 func getUsers(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	users, err := generated.GetUsers(ctx, client)
+	users, err := query.Dispatch[query.ListUsersQuery, *generated.GetUsersResponse](ctx, queryBus, query.ListUsersQuery{})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to fetch users: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
 
-	fmt.Println(users.Users[0].Age)
-
 	c.JSON(http.StatusOK, gin.H{
 		"data":  users,
 		"count": len(users.GetUsers()),
@@ -110,18 +205,14 @@ func getUsers(c *gin.Context) {
 
 func getUserByID(c *gin.Context) {
 	id := c.Param("id")
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	user, err := generated.GetUser(ctx, client, id)
+	user, err := query.Dispatch[query.FindUserByIDQuery, *generated.GetUserResponse](ctx, queryBus, query.FindUserByIDQuery{ID: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to fetch user: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
-
-	// Check if user exists by checking if the response has data
-	if user == nil {
+	if user.GetUser() == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
 		})
@@ -135,13 +226,11 @@ func getUserByID(c *gin.Context) {
 
 func searchUsers(c *gin.Context) {
 	name := c.Param("name")
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	users, err := generated.SearchUsers(ctx, client, name)
+	users, err := query.Dispatch[query.SearchUsersByNameQuery, *generated.SearchUsersResponse](ctx, queryBus, query.SearchUsersByNameQuery{Name: name})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to search users: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
 
@@ -153,13 +242,11 @@ func searchUsers(c *gin.Context) {
 }
 
 func getPosts(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	posts, err := generated.GetPosts(ctx, client)
+	posts, err := query.Dispatch[query.ListPostsQuery, *generated.GetPostsResponse](ctx, queryBus, query.ListPostsQuery{})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to fetch posts: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
 
@@ -171,17 +258,14 @@ func getPosts(c *gin.Context) {
 
 func getPostByID(c *gin.Context) {
 	id := c.Param("id")
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	post, err := generated.GetPost(ctx, client, id)
+	post, err := query.Dispatch[query.FindPostByIDQuery, *generated.GetPostResponse](ctx, queryBus, query.FindPostByIDQuery{ID: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to fetch post: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
-
-	if post == nil {
+	if post.GetPost() == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Post not found",
 		})
@@ -193,6 +277,46 @@ func getPostByID(c *gin.Context) {
 	})
 }
 
+// getPostsFeed lists every post with its author embedded. Each post names
+// its author by ID, so naively fetching them one at a time would be an
+// N+1 query; instead every author lookup goes through the request's
+// DataLoader, which coalesces them into a single batched call.
+func getPostsFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	posts, err := query.Dispatch[query.ListPostsQuery, *generated.GetPostsResponse](ctx, queryBus, query.ListPostsQuery{})
+	if err != nil {
+		respondGQLError(c, err)
+		return
+	}
+
+	userLoader := loader.From(ctx)
+
+	items := posts.GetPosts()
+	feed := make([]gin.H, len(items))
+
+	var wg sync.WaitGroup
+	for i, post := range items {
+		i, post := i, post
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var author *generated.User
+			if userLoader != nil {
+				author, _ = userLoader.Load(ctx, post.AuthorId)
+			}
+			feed[i] = gin.H{"post": post, "author": author}
+		}()
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  feed,
+		"count": len(feed),
+	})
+}
+
 func createUser(c *gin.Context) {
 	var req struct {
 		Name  string `json:"name" binding:"required"`
@@ -207,15 +331,18 @@ func createUser(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	user, err := generated.CreateUser(ctx, client, req.Name, req.Email, req.Age)
+	user, err := command.Dispatch[command.CreateUserCommand, *generated.CreateUserResponse](ctx, commandBus, command.CreateUserCommand{
+		Name:  req.Name,
+		Email: req.Email,
+		Age:   req.Age,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to create user: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
+	_ = responseCache.InvalidateTag(ctx, "users")
 
 	c.JSON(http.StatusCreated, gin.H{
 		"data":    user.GetCreateUser(),
@@ -239,22 +366,25 @@ func updateUser(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	user, err := generated.UpdateUser(ctx, client, id, req.Name, req.Email, req.Age)
+	user, err := command.Dispatch[command.UpdateUserCommand, *generated.UpdateUserResponse](ctx, commandBus, command.UpdateUserCommand{
+		ID:    id,
+		Name:  req.Name,
+		Email: req.Email,
+		Age:   req.Age,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to update user: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
-
-	if user == nil {
+	if user.GetUpdateUser() == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
 		})
 		return
 	}
+	_ = responseCache.InvalidateTag(ctx, "users")
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":    user.GetUpdateUser(),
@@ -264,22 +394,21 @@ func updateUser(c *gin.Context) {
 
 func deleteUser(c *gin.Context) {
 	id := c.Param("id")
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	result, err := generated.DeleteUser(ctx, client, id)
+	result, err := command.Dispatch[command.DeleteUserCommand, *generated.DeleteUserResponse](ctx, commandBus, command.DeleteUserCommand{ID: id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to delete user: %v", err),
-		})
+		respondGQLError(c, err)
 		return
 	}
 
-	if !result.DeleteUser {
+	if !result.GetDeleteUser() {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
 		})
 		return
 	}
+	_ = responseCache.InvalidateTag(ctx, "users")
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User deleted successfully",